@@ -1,16 +1,24 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	b64 "encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"text/template"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientauth "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
@@ -18,8 +26,13 @@ import (
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"gitlab.com/gridscale/gscloud/endpoints"
+	"gitlab.com/gridscale/gscloud/internal/credcache"
 )
 
+// paasServiceTemplateTypeKubernetes is the PaaS service template type that
+// identifies a managed kubernetes cluster among all PaaS services.
+const paasServiceTemplateTypeKubernetes = "kubernetes"
+
 // clusterCmd represents the cluster command
 var clusterCmd = &cobra.Command{
 	Use:   "cluster",
@@ -36,13 +49,38 @@ var kubernetesCmd = &cobra.Command{
 
 // saveKubeconfigCmd represents the kubeconfig command
 var saveKubeconfigCmd = &cobra.Command{
-	Use:   "save-kubeconfig",
-	Short: "Saves configuration of the given cluster into the provided kubeconfig",
-	Long:  "Saves configuration of the given cluster into the provided kubeconfig or KUBECONFIG ENV. variable",
+	Use:     "save-kubeconfig",
+	Aliases: []string{"login"},
+	Short:   "Saves configuration of the given cluster into the provided kubeconfig",
+	Long:    "Saves configuration of the given cluster into the provided kubeconfig or KUBECONFIG ENV. variable",
 	Run: func(cmd *cobra.Command, args []string) {
 		kubeConfigFile, _ := cmd.Flags().GetString("kubeconfig")
 		clusterID, _ := cmd.Flags().GetString("cluster")
 		credentialPlugin, _ := cmd.Flags().GetBool("credential-plugin")
+		contextName, _ := cmd.Flags().GetString("context-name")
+		userName, _ := cmd.Flags().GetString("user-name")
+		namespace, _ := cmd.Flags().GetString("namespace")
+		mergeOnly, _ := cmd.Flags().GetBool("merge-only")
+		setCurrent, _ := cmd.Flags().GetBool("set-current")
+		pruneExpired, _ := cmd.Flags().GetBool("prune-expired")
+		authMode, _ := cmd.Flags().GetString("auth-mode")
+		oidcExtraScopes, _ := cmd.Flags().GetStringSlice("oidc-extra-scope")
+		if authMode == "" {
+			authMode = "cert"
+			if credentialPlugin {
+				authMode = "exec"
+			}
+		}
+		switch authMode {
+		case "cert", "exec", "oidc", "socket":
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid --auth-mode %q, must be one of cert|exec|oidc|socket\n", authMode)
+			os.Exit(1)
+		}
+		kubeconfigTemplatePath, _ := cmd.Flags().GetString("kubeconfig-template")
+		if kubeconfigTemplatePath == "" {
+			kubeconfigTemplatePath = viper.GetString("kubeconfig_template")
+		}
 		kubeConfigEnv := os.Getenv("KUBECONFIG")
 
 		pathOptions := clientcmd.NewDefaultPathOptions()
@@ -64,7 +102,7 @@ var saveKubeconfigCmd = &cobra.Command{
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
-		newKubeConfig := fetchKubeConfigFromProvider(clusterID)
+		newKubeConfig, oidc, ttl := fetchKubeConfigFromProvider(clusterID)
 		if len(newKubeConfig.Clusters) == 0 || len(newKubeConfig.Users) == 0 {
 			fmt.Fprintln(os.Stderr, "Error: Invaild kubeconfig")
 			os.Exit(1)
@@ -72,22 +110,86 @@ var saveKubeconfigCmd = &cobra.Command{
 		c := newKubeConfig.Clusters[0]
 		u := newKubeConfig.Users[0]
 
+		clusterName := c.Name
+		userEntryName := u.Name
+		if contextName != "" {
+			clusterName = contextName
+			userEntryName = contextName
+		}
+		if userName != "" {
+			userEntryName = userName
+		}
+		contextKey := newKubeConfig.CurrentContext
+		if contextName != "" {
+			contextKey = contextName
+		}
+
 		certificateAuthorityData, err := b64.StdEncoding.DecodeString(c.Cluster.CertificateAuthorityData)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
 
-		currentKubeConfig.Clusters[c.Name] = &clientcmdapi.Cluster{
+		if pruneExpired {
+			pruneStaleKubernetesEntries(currentKubeConfig)
+		}
+
+		if kubeconfigTemplatePath != "" {
+			templateData := kubeconfigTemplateData{
+				Server:                   c.Cluster.Server,
+				CertificateAuthorityData: c.Cluster.CertificateAuthorityData,
+				ClusterID:                clusterID,
+				ClusterName:              clusterName,
+				User:                     userEntryName,
+				Account:                  account,
+				Now:                      time.Now(),
+				Expiration:               time.Now().Add(ttl),
+			}
+			if err := applyKubeconfigTemplate(kubeconfigTemplatePath, currentKubeConfig, templateData); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+
+			// The template owns the context entry it rendered into
+			// currentKubeConfig, including any context-level extensions;
+			// only synthesize one here if the template left it out, and
+			// only fill in Namespace if the template didn't set one.
+			if ctx, ok := currentKubeConfig.Contexts[contextKey]; ok {
+				if namespace != "" && ctx.Namespace == "" {
+					ctx.Namespace = namespace
+				}
+			} else {
+				currentKubeConfig.Contexts[contextKey] = &clientcmdapi.Context{
+					Cluster:   clusterName,
+					AuthInfo:  userEntryName,
+					Namespace: namespace,
+				}
+			}
+			if !mergeOnly && setCurrent {
+				currentKubeConfig.CurrentContext = contextKey
+			}
+
+			recordManagedEntry(clusterID, managedKubeconfigEntry{
+				ClusterUUID: clusterID,
+				ClusterName: clusterName,
+				UserName:    userEntryName,
+				ContextName: contextKey,
+			})
+
+			if err := clientcmd.ModifyConfig(pathOptions, *currentKubeConfig, true); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		currentKubeConfig.Clusters[clusterName] = &clientcmdapi.Cluster{
 			Server:                   c.Cluster.Server,
 			CertificateAuthorityData: certificateAuthorityData,
 		}
-		currentKubeConfig.AuthInfos[u.Name] = &clientcmdapi.AuthInfo{
-			ClientCertificate: u.User.ClientKeyData,
-			ClientKey:         u.User.ClientCertificateData,
-		}
-		if credentialPlugin {
-			currentKubeConfig.AuthInfos[u.Name] = &clientcmdapi.AuthInfo{
+		switch authMode {
+		case "exec":
+			currentKubeConfig.AuthInfos[userEntryName] = &clientcmdapi.AuthInfo{
 				Exec: &clientcmdapi.ExecConfig{
 					APIVersion: clientauth.SchemeGroupVersion.String(),
 					Command:    cliPath(),
@@ -104,7 +206,34 @@ var saveKubeconfigCmd = &cobra.Command{
 					},
 				},
 			}
-		} else {
+		case "socket":
+			// Point kubectl directly at the cert/key files refresh-daemon
+			// keeps rotated on disk, so every kubectl invocation picks up
+			// a fresh credential without forking a gscloud process for
+			// each call (high-QPS/controller clients are the reason this
+			// mode exists).
+			currentKubeConfig.AuthInfos[userEntryName] = &clientcmdapi.AuthInfo{
+				ClientCertificate: cachedClientCertificatePath(clusterID),
+				ClientKeyFile:     cachedClientKeyPath(clusterID),
+			}
+		case "oidc":
+			if oidc == nil {
+				fmt.Fprintln(os.Stderr, "Error: cluster does not expose an OIDC provider configuration")
+				os.Exit(1)
+			}
+
+			currentKubeConfig.AuthInfos[userEntryName] = &clientcmdapi.AuthInfo{
+				AuthProvider: &clientcmdapi.AuthProviderConfig{
+					Name: "oidc",
+					Config: map[string]string{
+						"idp-issuer-url": oidc.IssuerURL,
+						"client-id":      oidc.ClientID,
+						"client-secret":  oidc.ClientSecret,
+						"extra-scopes":   strings.Join(oidcExtraScopes, ","),
+					},
+				},
+			}
+		case "cert":
 			clientCertificateData, err := b64.StdEncoding.DecodeString(u.User.ClientCertificateData)
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -117,17 +246,27 @@ var saveKubeconfigCmd = &cobra.Command{
 				os.Exit(1)
 			}
 
-			currentKubeConfig.AuthInfos[u.Name] = &clientcmdapi.AuthInfo{
+			currentKubeConfig.AuthInfos[userEntryName] = &clientcmdapi.AuthInfo{
 				ClientCertificateData: clientCertificateData,
 				ClientKeyData:         clientKeyData,
 			}
 		}
 
-		currentKubeConfig.Contexts[newKubeConfig.CurrentContext] = &clientcmdapi.Context{
-			Cluster:  c.Name,
-			AuthInfo: u.Name,
+		currentKubeConfig.Contexts[contextKey] = &clientcmdapi.Context{
+			Cluster:   clusterName,
+			AuthInfo:  userEntryName,
+			Namespace: namespace,
+		}
+		if !mergeOnly && setCurrent {
+			currentKubeConfig.CurrentContext = contextKey
 		}
-		currentKubeConfig.CurrentContext = newKubeConfig.CurrentContext
+
+		recordManagedEntry(clusterID, managedKubeconfigEntry{
+			ClusterUUID: clusterID,
+			ClusterName: clusterName,
+			UserName:    userEntryName,
+			ContextName: contextKey,
+		})
 
 		err = clientcmd.ModifyConfig(pathOptions, *currentKubeConfig, true)
 		if err != nil {
@@ -144,6 +283,67 @@ func init() {
 	saveKubeconfigCmd.Flags().String("cluster", "", "The cluster's uuid")
 	saveKubeconfigCmd.MarkFlagRequired("cluster")
 	saveKubeconfigCmd.Flags().Bool("credential-plugin", false, "Enables credential plugin authentication method (exec-credential)")
+	saveKubeconfigCmd.Flags().String("context-name", "", "(optional) deterministic name to use for the cluster, user and context entries instead of the names returned by the API")
+	saveKubeconfigCmd.Flags().String("user-name", "", "(optional) name to use for the user entry, overrides --context-name for the user entry only")
+	saveKubeconfigCmd.Flags().String("namespace", "", "(optional) default namespace to set on the created context")
+	saveKubeconfigCmd.Flags().Bool("merge-only", false, "Merge cluster, user and context entries without changing the current context")
+	saveKubeconfigCmd.Flags().Bool("set-current", true, "Set the merged context as the current context")
+	saveKubeconfigCmd.Flags().Bool("prune-expired", false, "Remove gscloud-managed cluster, user and context entries for clusters that no longer exist upstream")
+	saveKubeconfigCmd.Flags().String("auth-mode", "", "Authentication method to embed in the kubeconfig. One of: cert|exec|oidc|socket (default cert, or exec when --credential-plugin is set)")
+	saveKubeconfigCmd.Flags().StringSlice("oidc-extra-scope", nil, "Additional OAuth2 scopes to request when --auth-mode=oidc")
+	saveKubeconfigCmd.Flags().String("kubeconfig-template", "", "(optional) path to a Go text/template that fully controls the cluster, user and context entries written to the kubeconfig, overriding --auth-mode; can also be set via kubeconfig_template in the config file")
+}
+
+// kubeconfigTemplateData is passed to a user-supplied --kubeconfig-template
+// so organizations can fully control the shape of the cluster, user and
+// context entries gscloud writes, e.g. to add proxy-url, tls-server-name
+// or custom extensions that --auth-mode alone cannot express.
+type kubeconfigTemplateData struct {
+	Server                   string
+	CertificateAuthorityData string
+	ClusterID                string
+	ClusterName              string
+	User                     string
+	Account                  string
+	Now                      time.Time
+	Expiration               time.Time
+}
+
+// applyKubeconfigTemplate renders the template at templatePath with data,
+// parses the result as a kubeconfig and merges its clusters, users and
+// contexts into kc.
+func applyKubeconfigTemplate(templatePath string, kc *clientcmdapi.Config, data kubeconfigTemplateData) error {
+	tmplBytes, err := os.ReadFile(templatePath)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New(filepath.Base(templatePath)).Parse(string(tmplBytes))
+	if err != nil {
+		return err
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return err
+	}
+
+	renderedConfig, err := clientcmd.Load(rendered.Bytes())
+	if err != nil {
+		return err
+	}
+
+	for name, cluster := range renderedConfig.Clusters {
+		kc.Clusters[name] = cluster
+	}
+	for name, authInfo := range renderedConfig.AuthInfos {
+		kc.AuthInfos[name] = authInfo
+	}
+	for name, context := range renderedConfig.Contexts {
+		kc.Contexts[name] = context
+	}
+
+	return nil
 }
 
 // execCredentialCmd represents the getCertificate command
@@ -154,6 +354,18 @@ var execCredentialCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		kubeConfigFile, _ := cmd.Flags().GetString("kubeconfig")
 		clusterID, _ := cmd.Flags().GetString("cluster")
+		socketPath, _ := cmd.Flags().GetString("socket")
+
+		if socketPath != "" {
+			execCredentialJSON, err := fetchExecCredentialFromSocket(socketPath, clusterID)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			// this output will be used by kubectl
+			fmt.Println(execCredentialJSON)
+			return
+		}
 
 		kubectlDefaults := clientcmd.NewDefaultPathOptions()
 		if kubeConfigFile != "" {
@@ -165,41 +377,43 @@ var execCredentialCmd = &cobra.Command{
 			fmt.Fprintln(os.Stderr, err)
 		}
 
-		execCredential, err := loadCachedKubeConfig(clusterID)
+		execCredential, stale, err := loadCachedKubeConfig(clusterID)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 		}
 
-		if execCredential == nil {
-			newKubeConfig := fetchKubeConfigFromProvider(clusterID)
-			if len(newKubeConfig.Users) != 0 {
-				u := newKubeConfig.Users[0]
-				clientKeyData, err := b64.StdEncoding.DecodeString(u.User.ClientKeyData)
-				if err != nil {
-					fmt.Println(err)
-				}
-				clientCertificateData, err := b64.StdEncoding.DecodeString(u.User.ClientCertificateData)
-				if err != nil {
-					fmt.Println(err)
-				}
-
-				execCredential = &clientauth.ExecCredential{
-					TypeMeta: metav1.TypeMeta{
-						Kind:       "ExecCredential",
-						APIVersion: clientauth.SchemeGroupVersion.String(),
-					},
-					Status: &clientauth.ExecCredentialStatus{
-						ClientKeyData:         string(clientKeyData),
-						ClientCertificateData: string(clientCertificateData),
-						ExpirationTimestamp:   &metav1.Time{Time: time.Now().Add(time.Hour)},
-					},
-				}
+		if execCredential == nil || stale {
+			// Only one process should call renew_credentials at a time;
+			// everyone else waits for the lock and then rechecks the
+			// cache, which the lock holder will have just refreshed.
+			unlock, lockErr := credcache.Lock(cachedKubeConfigLockPath(clusterID))
+			if lockErr != nil {
+				fmt.Fprintln(os.Stderr, lockErr)
+			} else {
+				defer unlock()
+			}
 
-				if err := cacheKubeConfig(clusterID, execCredential); err != nil {
-					fmt.Fprintln(os.Stderr, err)
-				}
+			var lockedCred *clientauth.ExecCredential
+			var lockedStale bool
+			if lockedCred, lockedStale, err = loadCachedKubeConfig(clusterID); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			if lockedCred != nil {
+				execCredential, stale = lockedCred, lockedStale
 			}
+		}
 
+		if execCredential == nil || stale {
+			// A refresh failure must not throw away a still-valid, merely
+			// stale cached credential: kubectl should keep working until
+			// the credential truly expires, not block on every hiccup.
+			refreshed, refreshErr := refreshCredentialFromProvider(clusterID)
+			if refreshErr != nil {
+				fmt.Fprintln(os.Stderr, refreshErr)
+			}
+			if refreshed != nil {
+				execCredential = refreshed
+			}
 		}
 		if execCredential == nil {
 			fmt.Println("Error: Could not retrieve kubeconfig from provider for account: ", account)
@@ -219,9 +433,379 @@ func init() {
 	execCredentialCmd.Flags().String("kubeconfig", "", "(optional) absolute path to the kubeconfig file")
 	execCredentialCmd.Flags().String("cluster", "", "The cluster's uuid")
 	execCredentialCmd.MarkFlagRequired("cluster")
+	execCredentialCmd.Flags().String("socket", "", "(optional) path to a credential-server unix socket; when set, the credential is fetched from the socket instead of being resolved in-process")
 }
 
-func fetchKubeConfigFromProvider(id string) *kubeConfig {
+// fetchExecCredentialFromSocket queries a running `credential-server` over
+// its unix socket and returns the raw ExecCredential JSON it replies with.
+func fetchExecCredentialFromSocket(socketPath, clusterID string) (string, error) {
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+
+	resp, err := httpClient.Get("http://credential-server/exec-credential?cluster=" + clusterID)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("credential-server: %s", string(body))
+	}
+
+	return string(body), nil
+}
+
+// refreshDaemonCmd represents the refresh-daemon command
+var refreshDaemonCmd = &cobra.Command{
+	Use:   "refresh-daemon",
+	Short: "Keeps a cached cluster credential fresh in the background",
+	Long:  "refresh-daemon periodically renews the cached exec-credential for a cluster once it goes stale, ahead of its expiration, so that kubectl invocations never block on a synchronous renewal. Intended to run as a long-lived user systemd/launchd unit.",
+	Run: func(cmd *cobra.Command, args []string) {
+		clusterID, _ := cmd.Flags().GetString("cluster")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		for {
+			refreshCredentialIfStale(clusterID)
+			time.Sleep(interval)
+		}
+	},
+}
+
+func init() {
+	clusterCmd.AddCommand(refreshDaemonCmd)
+	refreshDaemonCmd.Flags().String("cluster", "", "The cluster's uuid")
+	refreshDaemonCmd.MarkFlagRequired("cluster")
+	refreshDaemonCmd.Flags().Duration("interval", time.Minute, "How often to check whether the cached credential needs renewing")
+}
+
+// refreshCredentialIfStale renews the cached exec-credential for
+// clusterID if it is missing or has gone stale, taking the same lock
+// exec-credential uses so it doesn't race a kubectl-triggered refresh.
+func refreshCredentialIfStale(clusterID string) {
+	if cred, stale, _ := loadCachedKubeConfig(clusterID); cred != nil && !stale {
+		return
+	}
+
+	unlock, err := credcache.Lock(cachedKubeConfigLockPath(clusterID))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	defer unlock()
+
+	if cred, stale, _ := loadCachedKubeConfig(clusterID); cred != nil && !stale {
+		return
+	}
+
+	if _, err := refreshCredentialFromProvider(clusterID); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// credentialServerCmd represents the credential-server command
+var credentialServerCmd = &cobra.Command{
+	Use:   "credential-server",
+	Short: "Runs a local credential server implementing the ExecCredential protocol over a unix socket",
+	Long:  "credential-server exposes the same credential lookup and refresh logic as exec-credential over a local unix socket, so high-QPS clients such as controller-runtime or kubectl --watch can fetch credentials without forking a new gscloud process for every call. Safe to run as a long-lived user systemd/launchd unit.",
+	Run: func(cmd *cobra.Command, args []string) {
+		socketPath, _ := cmd.Flags().GetString("socket")
+		if socketPath == "" {
+			socketPath = defaultCredentialServerSocketPath()
+		}
+
+		if err := os.MkdirAll(filepath.Dir(socketPath), os.FileMode(0700)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		listener, err := net.Listen("unix", socketPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer listener.Close()
+
+		if err := os.Chmod(socketPath, os.FileMode(0600)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/exec-credential", handleExecCredentialRequest)
+
+		fmt.Fprintf(os.Stderr, "credential-server listening on %s\n", socketPath)
+		if err := http.Serve(listener, mux); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	clusterCmd.AddCommand(credentialServerCmd)
+	credentialServerCmd.Flags().String("socket", "", "(optional) path to the unix socket to listen on, defaults to a well-known path under the cache directory")
+}
+
+func defaultCredentialServerSocketPath() string {
+	return filepath.Join(kubeConfigCachePath(), "credential-server.sock")
+}
+
+// handleExecCredentialRequest serves GET /exec-credential?cluster=<uuid>,
+// reusing the same cache, locking and refresh logic as exec-credential.
+func handleExecCredentialRequest(w http.ResponseWriter, r *http.Request) {
+	clusterID := r.URL.Query().Get("cluster")
+	if clusterID == "" {
+		http.Error(w, "missing cluster query parameter", http.StatusBadRequest)
+		return
+	}
+
+	execCredential, stale, err := loadCachedKubeConfig(clusterID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if execCredential == nil || stale {
+		unlock, lockErr := credcache.Lock(cachedKubeConfigLockPath(clusterID))
+		if lockErr == nil {
+			defer unlock()
+		}
+
+		lockedCred, lockedStale, lockedErr := loadCachedKubeConfig(clusterID)
+		if lockedErr != nil {
+			http.Error(w, lockedErr.Error(), http.StatusInternalServerError)
+			return
+		}
+		if lockedCred != nil {
+			execCredential, stale = lockedCred, lockedStale
+		}
+	}
+
+	if execCredential == nil || stale {
+		// A refresh failure must not throw away a still-valid, merely
+		// stale cached credential; only error out if there is nothing to
+		// fall back to.
+		refreshed, refreshErr := refreshCredentialFromProvider(clusterID)
+		if refreshed != nil {
+			execCredential = refreshed
+		} else if execCredential == nil {
+			if refreshErr != nil {
+				http.Error(w, refreshErr.Error(), http.StatusInternalServerError)
+			} else {
+				http.Error(w, "could not retrieve credential from provider", http.StatusBadGateway)
+			}
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(execCredential); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// clusterListCmd represents the cluster list command
+var clusterListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists all managed kubernetes clusters",
+	Long:  "Lists all managed kubernetes clusters available to the configured account",
+	Run: func(cmd *cobra.Command, args []string) {
+		clusters, err := fetchKubernetesClusters()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		format, _ := cmd.Flags().GetString("output")
+		printKubernetesClusters(clusters, format)
+	},
+}
+
+// clusterGetCmd represents the cluster get command
+var clusterGetCmd = &cobra.Command{
+	Use:   "get <cluster-uuid>",
+	Short: "Shows details of a managed kubernetes cluster",
+	Long:  "Shows detailed information about a single managed kubernetes cluster",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		clusterID := args[0]
+
+		r := request{
+			uri:    path.Join(apiPaasServiceBase, clusterID),
+			method: http.MethodGet,
+		}
+
+		var paaSService endpoints.PaaSService
+		r.execute(*client, &paaSService)
+
+		format, _ := cmd.Flags().GetString("output")
+		printKubernetesCluster(clusterID, paaSService, format)
+	},
+}
+
+func init() {
+	clusterCmd.AddCommand(clusterListCmd)
+	clusterListCmd.Flags().StringP("output", "o", "table", "Output format. One of: table|json|yaml")
+
+	clusterCmd.AddCommand(clusterGetCmd)
+	clusterGetCmd.Flags().StringP("output", "o", "table", "Output format. One of: table|json|yaml")
+}
+
+// fetchKubernetesClusters retrieves all PaaS services of the account and
+// returns only those that are managed kubernetes clusters, keyed by UUID.
+// It returns an error, without a partial result, if the list could not be
+// reliably retrieved: callers that use the result to decide what no
+// longer exists (e.g. pruning) must not treat a failed fetch as "no
+// clusters".
+func fetchKubernetesClusters() (map[string]endpoints.PaaSService, error) {
+	r := request{
+		uri:    apiPaasServiceBase,
+		method: http.MethodGet,
+	}
+
+	var list endpoints.PaaSServiceList
+	if err := r.execute(*client, &list); err != nil {
+		return nil, err
+	}
+
+	return filterKubernetesClusters(list), nil
+}
+
+// filterKubernetesClusters returns only the managed kubernetes clusters
+// out of a decoded PaaS service list, keyed by UUID.
+func filterKubernetesClusters(list endpoints.PaaSServiceList) map[string]endpoints.PaaSService {
+	clusters := make(map[string]endpoints.PaaSService)
+	for id, service := range list.PaaSServices {
+		if service.Properties.ServiceTemplateType != paasServiceTemplateTypeKubernetes {
+			continue
+		}
+		clusters[id] = service
+	}
+
+	return clusters
+}
+
+func printKubernetesClusters(clusters map[string]endpoints.PaaSService, format string) {
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(clusters, "", "    ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		fmt.Println(string(b))
+	case "yaml":
+		b, err := yaml.Marshal(clusters)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		fmt.Print(string(b))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "UUID\tNAME\tSTATUS\tK8S VERSION\tNODE POOLS\tCREDENTIALS EXPIRE")
+		for id, service := range clusters {
+			p := service.Properties
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				id,
+				p.Name,
+				p.Status,
+				p.Parameters.KubernetesVersion,
+				formatNodePools(p.Parameters.NodePools),
+				formatCredentialExpiration(p.Credentials),
+			)
+		}
+		w.Flush()
+	}
+}
+
+func printKubernetesCluster(id string, service endpoints.PaaSService, format string) {
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(service, "", "    ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		fmt.Println(string(b))
+	case "yaml":
+		b, err := yaml.Marshal(service)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		fmt.Print(string(b))
+	default:
+		p := service.Properties
+		fmt.Printf("UUID:               %s\n", id)
+		fmt.Printf("Name:               %s\n", p.Name)
+		fmt.Printf("Status:             %s\n", p.Status)
+		fmt.Printf("K8s Version:        %s\n", p.Parameters.KubernetesVersion)
+		fmt.Printf("Security Zone:      %s\n", p.Parameters.SecurityZoneUUID)
+		fmt.Printf("Node Pools:         %s\n", formatNodePools(p.Parameters.NodePools))
+		fmt.Printf("Networks:           %s\n", formatNetworks(p.Relations.Networks))
+		fmt.Printf("Credentials Expire: %s\n", formatCredentialExpiration(p.Credentials))
+	}
+}
+
+func formatNodePools(pools []endpoints.PaaSKubernetesNodePool) string {
+	if len(pools) == 0 {
+		return "-"
+	}
+
+	parts := make([]string, 0, len(pools))
+	for _, pool := range pools {
+		parts = append(parts, fmt.Sprintf("%s:%d", pool.Name, pool.NodeCount))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatNetworks(networks []endpoints.PaaSServiceNetworkRelation) string {
+	if len(networks) == 0 {
+		return "-"
+	}
+
+	parts := make([]string, 0, len(networks))
+	for _, n := range networks {
+		parts = append(parts, fmt.Sprintf("%s (%s)", n.NetworkName, n.NetworkUUID))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatCredentialExpiration(credentials []endpoints.PaaSServiceCredential) string {
+	if len(credentials) == 0 {
+		return "-"
+	}
+	return credentials[0].ExpirationTime.Format(time.RFC3339)
+}
+
+// oidcProviderConfig captures the OIDC issuer configuration of a managed
+// kubernetes cluster, as surfaced by the PaaS API alongside its
+// kubeconfig credentials.
+type oidcProviderConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+}
+
+// fetchKubeConfigFromProvider renews and retrieves clusterID's kubeconfig
+// from the PaaS API. It also returns the cluster's OIDC provider
+// configuration, or nil if the cluster does not expose one.
+func fetchKubeConfigFromProvider(id string) (*kubeConfig, *oidcProviderConfig, time.Duration) {
 	var kc kubeConfig
 
 	// generate kubeconfig
@@ -249,7 +833,88 @@ func fetchKubeConfigFromProvider(id string) *kubeConfig {
 		}
 	}
 
-	return &kc
+	var oidc *oidcProviderConfig
+	if paaSService.Properties.OIDC.IssuerURL != "" {
+		oidc = &oidcProviderConfig{
+			IssuerURL:    paaSService.Properties.OIDC.IssuerURL,
+			ClientID:     paaSService.Properties.OIDC.ClientID,
+			ClientSecret: paaSService.Properties.OIDC.ClientSecret,
+		}
+	}
+
+	ttl := time.Duration(paaSService.Properties.CredentialTTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	return &kc, oidc, ttl
+}
+
+// refreshCredentialFromProvider fetches a fresh client certificate for
+// clusterID from the PaaS API, caches it and returns it. It returns a nil
+// credential, without error, if the provider has no user for the cluster.
+func refreshCredentialFromProvider(clusterID string) (*clientauth.ExecCredential, error) {
+	newKubeConfig, _, ttl := fetchKubeConfigFromProvider(clusterID)
+	if len(newKubeConfig.Users) == 0 {
+		return nil, nil
+	}
+
+	u := newKubeConfig.Users[0]
+	clientKeyData, err := b64.StdEncoding.DecodeString(u.User.ClientKeyData)
+	if err != nil {
+		return nil, err
+	}
+	clientCertificateData, err := b64.StdEncoding.DecodeString(u.User.ClientCertificateData)
+	if err != nil {
+		return nil, err
+	}
+
+	execCredential := &clientauth.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ExecCredential",
+			APIVersion: clientauth.SchemeGroupVersion.String(),
+		},
+		Status: &clientauth.ExecCredentialStatus{
+			ClientKeyData:         string(clientKeyData),
+			ClientCertificateData: string(clientCertificateData),
+			ExpirationTimestamp:   &metav1.Time{Time: time.Now().Add(ttl)},
+		},
+	}
+
+	if err := cacheKubeConfig(clusterID, execCredential, ttl); err != nil {
+		return execCredential, err
+	}
+
+	if err := writeClientCertFiles(clusterID, clientCertificateData, clientKeyData); err != nil {
+		return execCredential, err
+	}
+
+	return execCredential, nil
+}
+
+// cachedClientCertificatePath and cachedClientKeyPath are the on-disk
+// locations refresh-daemon keeps rotated for --auth-mode=socket, so
+// kubectl can read a fresh client certificate straight off disk on every
+// invocation instead of forking a gscloud process.
+func cachedClientCertificatePath(id string) string {
+	return filepath.Join(kubeConfigCachePath(), id+".crt")
+}
+
+func cachedClientKeyPath(id string) string {
+	return filepath.Join(kubeConfigCachePath(), id+".key")
+}
+
+// writeClientCertFiles persists the decoded client certificate and key
+// for id to disk, owner-readable only.
+func writeClientCertFiles(id string, certificateData, keyData []byte) error {
+	cachePath := kubeConfigCachePath()
+	if err := os.MkdirAll(cachePath, os.FileMode(0700)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(cachedClientCertificatePath(id), certificateData, os.FileMode(0600)); err != nil {
+		return err
+	}
+	return os.WriteFile(cachedClientKeyPath(id), keyData, os.FileMode(0600))
 }
 
 func kubeConfigCachePath() string {
@@ -261,7 +926,30 @@ func cachedKubeConfigPath(id string) string {
 	return filepath.Join(kubeConfigCachePath(), id+".json")
 }
 
-func cacheKubeConfig(id string, execCredential *clientauth.ExecCredential) error {
+// cachedCredentialRecord is the on-disk representation of a cached
+// exec-credential: the credential body itself is AES-GCM sealed, and Tag
+// is an HMAC over (cluster ID, Expiration) so the expiration cannot be
+// extended by editing the file directly. StaleAt is the point at which
+// the credential should be proactively refreshed, ahead of Expiration.
+type cachedCredentialRecord struct {
+	Sealed     string `json:"sealed"`
+	Expiration string `json:"expiration"`
+	StaleAt    string `json:"staleAt"`
+	Tag        string `json:"tag"`
+}
+
+func credKeyring() credcache.Keyring {
+	return credcache.Keyring{Account: account, CfgFile: cfgFile}
+}
+
+func cachedKubeConfigLockPath(id string) string {
+	return cachedKubeConfigPath(id) + ".lock"
+}
+
+// cacheKubeConfig encrypts and persists execCredential for id. ttl is the
+// server-advertised credential lifetime and is used to compute when the
+// credential should be considered stale and proactively refreshed.
+func cacheKubeConfig(id string, execCredential *clientauth.ExecCredential, ttl time.Duration) error {
 	if execCredential.Status.ExpirationTimestamp.IsZero() {
 		return nil
 	}
@@ -271,6 +959,24 @@ func cacheKubeConfig(id string, execCredential *clientauth.ExecCredential) error
 		return err
 	}
 
+	plaintext, err := json.Marshal(execCredential)
+	if err != nil {
+		return err
+	}
+
+	kr := credKeyring()
+	sealed, err := kr.Seal(plaintext)
+	if err != nil {
+		return err
+	}
+
+	expirationTime := execCredential.Status.ExpirationTimestamp.Time
+	expiration := expirationTime.Format(time.RFC3339)
+	tag, err := kr.IntegrityTag(id, expiration)
+	if err != nil {
+		return err
+	}
+
 	path := cachedKubeConfigPath(id)
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(0600))
 	if err != nil {
@@ -278,35 +984,181 @@ func cacheKubeConfig(id string, execCredential *clientauth.ExecCredential) error
 	}
 	defer f.Close()
 
-	return json.NewEncoder(f).Encode(execCredential)
+	return json.NewEncoder(f).Encode(cachedCredentialRecord{
+		Sealed:     sealed,
+		Expiration: expiration,
+		StaleAt:    credcache.StaleAt(expirationTime, ttl).Format(time.RFC3339),
+		Tag:        tag,
+	})
 }
 
-func loadCachedKubeConfig(id string) (*clientauth.ExecCredential, error) {
+// loadCachedKubeConfig returns the cached credential for id, or nil if
+// there is none or it has fully expired; a fully expired file, or one
+// that fails its integrity check, is removed from disk. stale reports whether the credential is past its
+// StaleAt margin: callers should attempt a refresh, but a credential that
+// is stale-but-not-expired is still returned so they can fall back to it
+// if that refresh fails.
+func loadCachedKubeConfig(id string) (cred *clientauth.ExecCredential, stale bool, err error) {
+	cachePath := kubeConfigCachePath()
+	if err := credcache.CheckDirPermissions(cachePath); err != nil {
+		return nil, false, err
+	}
+
 	kubeConfigPath := cachedKubeConfigPath(id)
 	f, err := os.Open(kubeConfigPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			return nil, false, nil
 		}
 
-		return nil, err
+		return nil, false, err
 	}
-
 	defer f.Close()
 
+	if err := credcache.CheckFilePermissions(kubeConfigPath); err != nil {
+		return nil, false, err
+	}
+
+	var record cachedCredentialRecord
+	if err := json.NewDecoder(f).Decode(&record); err != nil {
+		return nil, false, err
+	}
+
+	kr := credKeyring()
+	if err := kr.VerifyIntegrityTag(id, record.Expiration, record.Tag); err != nil {
+		if err == credcache.ErrTampered {
+			_ = os.Remove(kubeConfigPath)
+		}
+		return nil, false, err
+	}
+
+	if staleAt, err := time.Parse(time.RFC3339, record.StaleAt); err == nil && !time.Now().Before(staleAt) {
+		stale = true
+	}
+
+	plaintext, err := kr.Open(record.Sealed)
+	if err != nil {
+		return nil, false, err
+	}
+
 	var execCredential *clientauth.ExecCredential
-	if err := json.NewDecoder(f).Decode(&execCredential); err != nil {
-		return nil, err
+	if err := json.Unmarshal(plaintext, &execCredential); err != nil {
+		return nil, false, err
 	}
 
 	timeStamp := execCredential.Status.ExpirationTimestamp
 
 	if execCredential.Status == nil || timeStamp.IsZero() || timeStamp.Time.Before(time.Now()) {
 		err = os.Remove(kubeConfigPath)
+		return nil, false, err
+	}
+
+	return execCredential, stale, nil
+}
+
+// managedKubeconfigEntry records which cluster, user and context entries
+// gscloud wrote into a kubeconfig for a given cluster, so they can be
+// identified and removed again once the cluster disappears upstream.
+type managedKubeconfigEntry struct {
+	ClusterUUID string `json:"clusterUUID"`
+	ClusterName string `json:"clusterName"`
+	UserName    string `json:"userName"`
+	ContextName string `json:"contextName"`
+}
+
+func managedEntriesPath() string {
+	return filepath.Join(kubeConfigCachePath(), "managed-entries.json")
+}
+
+func loadManagedEntries() (map[string]managedKubeconfigEntry, error) {
+	entries := make(map[string]managedKubeconfigEntry)
+
+	f, err := os.Open(managedEntriesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&entries); err != nil {
 		return nil, err
 	}
+	return entries, nil
+}
 
-	return execCredential, nil
+func saveManagedEntries(entries map[string]managedKubeconfigEntry) error {
+	cachePath := kubeConfigCachePath()
+	if err := os.MkdirAll(cachePath, os.FileMode(0700)); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(managedEntriesPath(), os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(0600))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(entries)
+}
+
+// recordManagedEntry remembers that gscloud wrote the given entry for
+// clusterID, so a later --prune-expired run can find and remove it.
+func recordManagedEntry(clusterID string, entry managedKubeconfigEntry) {
+	entries, err := loadManagedEntries()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	entries[clusterID] = entry
+	if err := saveManagedEntries(entries); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// pruneStaleKubernetesEntries removes kubeconfig entries and cached
+// exec-credentials that gscloud previously wrote for clusters that no
+// longer exist upstream.
+func pruneStaleKubernetesEntries(kc *clientcmdapi.Config) {
+	entries, err := loadManagedEntries()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	// Only prune when we positively know the account's current cluster
+	// set: a failed fetch must never be mistaken for "no clusters exist",
+	// or a transient API hiccup would wipe every managed entry.
+	valid, err := fetchKubernetesClusters()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	for clusterID, entry := range entries {
+		if _, ok := valid[clusterID]; ok {
+			continue
+		}
+
+		delete(kc.Clusters, entry.ClusterName)
+		delete(kc.AuthInfos, entry.UserName)
+		delete(kc.Contexts, entry.ContextName)
+		if kc.CurrentContext == entry.ContextName {
+			kc.CurrentContext = ""
+		}
+
+		if err := os.Remove(cachedKubeConfigPath(clusterID)); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		delete(entries, clusterID)
+	}
+
+	if err := saveManagedEntries(entries); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
 }
 
 func fileExists(filename string) bool {
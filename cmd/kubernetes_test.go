@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gitlab.com/gridscale/gscloud/endpoints"
+)
+
+func TestFilterKubernetesClusters(t *testing.T) {
+	fixture := `{
+		"paas_services": {
+			"uuid-k8s": {
+				"properties": {
+					"name": "prod",
+					"status": "active",
+					"service_template_type": "kubernetes",
+					"parameters": {"k8s_version": "1.29"}
+				}
+			},
+			"uuid-postgres": {
+				"properties": {
+					"name": "db",
+					"status": "active",
+					"service_template_type": "postgresql"
+				}
+			}
+		}
+	}`
+
+	var list endpoints.PaaSServiceList
+	if err := json.Unmarshal([]byte(fixture), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	clusters := filterKubernetesClusters(list)
+	if len(clusters) != 1 {
+		t.Fatalf("expected 1 kubernetes cluster, got %d", len(clusters))
+	}
+
+	cluster, ok := clusters["uuid-k8s"]
+	if !ok {
+		t.Fatalf("expected uuid-k8s to be present")
+	}
+	if cluster.Properties.Parameters.KubernetesVersion != "1.29" {
+		t.Fatalf("expected k8s version 1.29, got %q", cluster.Properties.Parameters.KubernetesVersion)
+	}
+}
@@ -0,0 +1,78 @@
+package endpoints
+
+import "time"
+
+// PaaSServiceList is the collection response the PaaS service list
+// endpoint returns: services keyed by UUID under "paas_services", the
+// same envelope convention other gridscale object collections use.
+type PaaSServiceList struct {
+	PaaSServices map[string]PaaSService `json:"paas_services"`
+}
+
+// PaaSService represents a PaaS service instance, such as a managed
+// kubernetes cluster.
+type PaaSService struct {
+	Properties PaaSServiceProperties `json:"properties"`
+}
+
+// PaaSServiceProperties holds the properties common to all PaaS service
+// types, plus the kubernetes-specific parameters and credentials used by
+// the managed kubernetes cluster commands.
+type PaaSServiceProperties struct {
+	Name                 string                   `json:"name"`
+	Status               string                   `json:"status"`
+	ServiceTemplateType  string                   `json:"service_template_type"`
+	CredentialTTLSeconds int                      `json:"credential_ttl_seconds"`
+	Credentials          []PaaSServiceCredential  `json:"credentials"`
+	Parameters           PaaSServiceParameters    `json:"parameters"`
+	Relations            PaaSServiceRelations     `json:"relations"`
+	OIDC                 PaaSKubernetesOIDCConfig `json:"oidc"`
+}
+
+// PaaSServiceParameters holds the managed-kubernetes-specific parameters
+// of a PaaS service.
+type PaaSServiceParameters struct {
+	KubernetesVersion string                   `json:"k8s_version"`
+	SecurityZoneUUID  string                   `json:"security_zone_uuid"`
+	NodePools         []PaaSKubernetesNodePool `json:"node_pools"`
+}
+
+// PaaSKubernetesNodePool describes a single node pool of a managed
+// kubernetes cluster.
+type PaaSKubernetesNodePool struct {
+	Name      string `json:"name"`
+	NodeCount int    `json:"node_count"`
+}
+
+// PaaSServiceRelations holds the other gridscale objects a PaaS service
+// is related to.
+type PaaSServiceRelations struct {
+	Networks []PaaSServiceNetworkRelation `json:"networks"`
+}
+
+// PaaSServiceNetworkRelation describes a network a PaaS service is
+// attached to.
+type PaaSServiceNetworkRelation struct {
+	NetworkUUID string `json:"network_uuid"`
+	NetworkName string `json:"network_name"`
+}
+
+// PaaSServiceCredential is a single set of credentials issued for a PaaS
+// service, such as a cluster's kubeconfig.
+type PaaSServiceCredential struct {
+	KubeConfig     string    `json:"kubeconfig"`
+	ExpirationTime time.Time `json:"expiration_time"`
+}
+
+// PaaSKubeCredentialBody is the (empty) request body sent to the
+// renew_credentials endpoint of a managed kubernetes cluster.
+type PaaSKubeCredentialBody struct{}
+
+// PaaSKubernetesOIDCConfig holds the OIDC issuer configuration of a
+// managed kubernetes cluster, present only when the cluster was
+// provisioned with OIDC authentication enabled.
+type PaaSKubernetesOIDCConfig struct {
+	IssuerURL    string `json:"issuer_url"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
@@ -0,0 +1,182 @@
+// Package credcache provides at-rest encryption and integrity checking
+// for credentials that gscloud caches on disk, such as the exec-credential
+// cache used by the kubernetes cluster commands.
+package credcache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+const keyringService = "gscloud-kubeconfig-cache"
+
+func init() {
+	// Go >=1.20 auto-seeds the global math/rand source unpredictably, but
+	// this package also needs to support older toolchains where it
+	// defaults to a fixed seed: without this, every gscloud process would
+	// draw the same first StaleAt jitter value, and racing invocations
+	// sharing a cache would all decide to refresh at once.
+	var seed [8]byte
+	if _, err := io.ReadFull(crand.Reader, seed[:]); err == nil {
+		rand.Seed(int64(binary.BigEndian.Uint64(seed[:])))
+	}
+}
+
+// ErrPermissionsTooOpen is returned when a cache directory or file is
+// readable or writable by anyone other than its owner.
+var ErrPermissionsTooOpen = errors.New("credcache: directory or file permissions are too open")
+
+// ErrTampered is returned when the integrity tag stored alongside a
+// cached credential does not match its contents.
+var ErrTampered = errors.New("credcache: cached credential failed integrity check")
+
+// Keyring derives the AES-256 key used to seal cached credentials for a
+// given account. It prefers the OS keyring and falls back to a key
+// derived from cfgFile's path and inode when no keyring backend is
+// available (e.g. headless CI).
+type Keyring struct {
+	Account string
+	CfgFile string
+}
+
+func (k Keyring) key() ([]byte, error) {
+	secret, err := keyring.Get(keyringService, k.Account)
+	if err != nil {
+		secret, err = k.fallbackSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:], nil
+}
+
+func (k Keyring) fallbackSecret() (string, error) {
+	info, err := os.Stat(k.CfgFile)
+	if err != nil {
+		return "", err
+	}
+
+	secret := fmt.Sprintf("%s:%d:%s", k.CfgFile, stableFileID(info), k.Account)
+
+	// Best effort: persist the derived secret in the keyring so future
+	// runs are stable even if the file is recreated. A missing keyring
+	// backend is not fatal, we just re-derive it every time.
+	_ = keyring.Set(keyringService, k.Account, secret)
+
+	return secret, nil
+}
+
+// Seal encrypts plaintext with AES-256-GCM and returns
+// base64(nonce||ciphertext).
+func (k Keyring) Seal(plaintext []byte) (string, error) {
+	gcm, err := k.gcm()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(crand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal.
+func (k Keyring) Open(encoded string) ([]byte, error) {
+	gcm, err := k.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("credcache: ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func (k Keyring) gcm() (cipher.AEAD, error) {
+	key, err := k.key()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// IntegrityTag computes an HMAC over (clusterID, expiration) so that a
+// cached credential cannot be tampered with to extend its own lifetime.
+func (k Keyring) IntegrityTag(clusterID, expiration string) (string, error) {
+	key, err := k.key()
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(clusterID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expiration))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyIntegrityTag returns ErrTampered if tag does not match the one
+// IntegrityTag would compute for (clusterID, expiration).
+func (k Keyring) VerifyIntegrityTag(clusterID, expiration, tag string) error {
+	expected, err := k.IntegrityTag(clusterID, expiration)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(tag)) {
+		return ErrTampered
+	}
+	return nil
+}
+
+// StaleAt returns the point in time at which a cached credential with the
+// given expiration and TTL should be proactively refreshed: comfortably
+// ahead of its hard expiration, and jittered so that many gscloud
+// processes sharing the same cache don't all decide to refresh at once.
+func StaleAt(expiration time.Time, ttl time.Duration) time.Time {
+	margin := ttl / 10
+	if want := 5 * time.Minute; margin < want {
+		margin = want
+	}
+	if margin > ttl/2 {
+		// For short-lived credentials a 5 minute (or 10%) margin would
+		// make them stale before they're even cached; cap it so at least
+		// half of the TTL is usable.
+		margin = ttl / 2
+	}
+	if margin <= 0 {
+		return expiration
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(margin/4 + 1)))
+	return expiration.Add(-margin + jitter)
+}
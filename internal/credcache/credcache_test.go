@@ -0,0 +1,79 @@
+package credcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testKeyring(t *testing.T) Keyring {
+	t.Helper()
+
+	cfgFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(cfgFile, []byte("{}"), 0600); err != nil {
+		t.Fatalf("write cfg file: %v", err)
+	}
+
+	return Keyring{Account: "test-account", CfgFile: cfgFile}
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	kr := testKeyring(t)
+
+	plaintext := []byte("super secret kubeconfig")
+	sealed, err := kr.Seal(plaintext)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	opened, err := kr.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if string(opened) != string(plaintext) {
+		t.Fatalf("got %q, want %q", opened, plaintext)
+	}
+}
+
+func TestVerifyIntegrityTagTampered(t *testing.T) {
+	kr := testKeyring(t)
+
+	tag, err := kr.IntegrityTag("cluster-id", "2099-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("IntegrityTag: %v", err)
+	}
+
+	if err := kr.VerifyIntegrityTag("cluster-id", "2099-01-01T00:00:00Z", tag); err != nil {
+		t.Fatalf("expected untampered tag to verify, got %v", err)
+	}
+
+	if err := kr.VerifyIntegrityTag("cluster-id", "2100-01-01T00:00:00Z", tag); err != ErrTampered {
+		t.Fatalf("expected ErrTampered for a tag computed over a different expiration, got %v", err)
+	}
+}
+
+func TestStaleAt(t *testing.T) {
+	expiration := time.Date(2099, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("long TTL uses the 10%-or-5-minute margin", func(t *testing.T) {
+		staleAt := StaleAt(expiration, time.Hour)
+		if !staleAt.After(expiration.Add(-10 * time.Minute)) {
+			t.Fatalf("staleAt %v is more than 10 minutes ahead of expiration %v", staleAt, expiration)
+		}
+		if !staleAt.Before(expiration) {
+			t.Fatalf("staleAt %v is not before expiration %v", staleAt, expiration)
+		}
+	})
+
+	t.Run("short TTL caps the margin at half the TTL", func(t *testing.T) {
+		ttl := 2 * time.Minute
+		staleAt := StaleAt(expiration, ttl)
+		if staleAt.Before(expiration.Add(-ttl)) {
+			t.Fatalf("staleAt %v is more than a full TTL ahead of expiration %v", staleAt, expiration)
+		}
+		if !staleAt.Before(expiration) {
+			t.Fatalf("staleAt %v is not before expiration %v", staleAt, expiration)
+		}
+	})
+}
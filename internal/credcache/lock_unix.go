@@ -0,0 +1,29 @@
+//go:build !windows
+
+package credcache
+
+import (
+	"os"
+	"syscall"
+)
+
+// Lock takes an exclusive, blocking OS-level lock on path for the
+// duration of a credential refresh, so that concurrent gscloud
+// invocations sharing the same cache don't all race the provider's
+// renew_credentials endpoint at once. The returned func releases it.
+func Lock(path string) (unlock func() error, err error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, os.FileMode(0600))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return func() error {
+		defer f.Close()
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}, nil
+}
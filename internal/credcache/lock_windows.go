@@ -0,0 +1,32 @@
+//go:build windows
+
+package credcache
+
+import (
+	"os"
+	"time"
+)
+
+// Lock takes an exclusive lock on path for the duration of a credential
+// refresh. Windows has no direct syscall.Flock equivalent wired up here,
+// so this falls back to a sibling lock file created with O_EXCL and
+// polled until it can be claimed.
+func Lock(path string) (unlock func() error, err error) {
+	lockPath := path + ".lock"
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0600))
+		if err == nil {
+			f.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return func() error {
+		return os.Remove(lockPath)
+	}, nil
+}
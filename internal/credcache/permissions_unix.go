@@ -0,0 +1,35 @@
+//go:build !windows
+
+package credcache
+
+import "os"
+
+// CheckDirPermissions verifies that dir is not accessible by anyone other
+// than its owner. Missing directories are not an error: there is simply
+// nothing cached yet.
+func CheckDirPermissions(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Mode().Perm()&^0700 != 0 {
+		return ErrPermissionsTooOpen
+	}
+	return nil
+}
+
+// CheckFilePermissions verifies that file is not readable or writable by
+// anyone other than its owner.
+func CheckFilePermissions(file string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&^0600 != 0 {
+		return ErrPermissionsTooOpen
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package credcache
+
+import "os"
+
+// CheckDirPermissions only reports an error if dir cannot be stat'ed for
+// a reason other than not existing; a missing dir is not an error. Windows
+// ACLs aren't comparable to the unix owner-only bits this package checks
+// for on other platforms, so no mode bits are checked here.
+func CheckDirPermissions(dir string) error {
+	_, err := os.Stat(dir)
+	if err != nil && os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// CheckFilePermissions verifies that file exists. See CheckDirPermissions
+// for why this doesn't enforce mode bits on Windows.
+func CheckFilePermissions(file string) error {
+	_, err := os.Stat(file)
+	return err
+}
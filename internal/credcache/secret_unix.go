@@ -0,0 +1,18 @@
+//go:build !windows
+
+package credcache
+
+import (
+	"os"
+	"syscall"
+)
+
+// stableFileID returns a value derived from info that stays stable across
+// process restarts but changes if the file is recreated, for use as part
+// of the fallback secret when no OS keyring backend is available.
+func stableFileID(info os.FileInfo) uint64 {
+	if sys, ok := info.Sys().(*syscall.Stat_t); ok {
+		return sys.Ino
+	}
+	return 0
+}
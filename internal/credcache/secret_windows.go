@@ -0,0 +1,20 @@
+//go:build windows
+
+package credcache
+
+import (
+	"os"
+	"syscall"
+)
+
+// stableFileID returns a value derived from info that stays stable across
+// process restarts but changes if the file is recreated, for use as part
+// of the fallback secret when no OS keyring backend is available. Windows
+// has no inode; the file's creation time serves the same purpose.
+func stableFileID(info os.FileInfo) uint64 {
+	if sys, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		ft := sys.CreationTime
+		return uint64(ft.HighDateTime)<<32 | uint64(ft.LowDateTime)
+	}
+	return 0
+}